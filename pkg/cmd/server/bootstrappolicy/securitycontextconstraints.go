@@ -12,7 +12,7 @@ const (
 
 	// SecurityContextConstraintRestricted is used as the name for the system default restricted scc.
 	SecurityContextConstraintRestricted     = "restricted"
-	SecurityContextConstraintRestrictedDesc = "restricted denies access to all host features and requires pods to be run with a UID, and SELinux context that are allocated to the namespace.  This is the most restrictive SCC."
+	SecurityContextConstraintRestrictedDesc = "restricted denies access to all host features and requires pods to be run with a UID, and SELinux context that are allocated to the namespace.  This is the most restrictive SCC granted to authenticated users by default; nonroot-strict is more restrictive still but is not granted to anyone by default."
 
 	// SecurityContextConstraintNonRoot is used as the name for the system default non-root scc.
 	SecurityContextConstraintNonRoot     = "nonroot"
@@ -32,6 +32,11 @@ const (
 	SecurityContextConstraintsAnyUID     = "anyuid"
 	SecurityContextConstraintsAnyUIDDesc = "anyuid provides all features of the restricted SCC but allows users to run with any UID and any GID.  This is the default SCC for authenticated users."
 
+	// SecurityContextConstraintNonRootStrict is used as the name for the system default
+	// non-root, non-root-group scc.
+	SecurityContextConstraintNonRootStrict     = "nonroot-strict"
+	SecurityContextConstraintNonRootStrictDesc = "nonroot-strict provides all features of the restricted SCC but additionally requires the container to run as a non-root UID and a non-root primary GID allocated to the namespace.  Not granted to any user or group by default."
+
 	// DescriptionAnnotation is the annotation used for attaching descriptions.
 	DescriptionAnnotation = "kubernetes.io/description"
 )
@@ -39,13 +44,71 @@ const (
 // GetBootstrapSecurityContextConstraints returns the slice of default SecurityContextConstraints
 // for system bootstrapping.  This method takes additional users and groups that should be added
 // to the strategies.  Use GetBoostrapSCCAccess to produce the default set of mappings.
+//
+// Volumes lists the volume plugins a pod admitted by the SCC is allowed to request; the SCC
+// admission plugin rejects any pod that asks for a volume type not on this list.  kapi.FSTypeAll
+// ("*") allows every plugin and is reserved for privileged.
+//
+// ReadOnlyRootFilesystem and AllowPrivilegeEscalation are opt-in knobs enforced by the same
+// admission plugin: a nil AllowPrivilegeEscalation leaves the decision to the pod, while a
+// non-nil false forbids requesting privilege escalation (and the no_new_privs flag is set on
+// the container) regardless of what the pod asks for.  ReadOnlyRootFilesystem defaults to false
+// (the pod's own request is honored); none of the bootstrap SCCs below force it.
+//
+// FSGroupStrategyMustRunAs and SupplementalGroupsStrategyMustRunAs both draw from the same GID
+// range allocated to the namespace, the same way RunAsUserStrategyMustRunAsRange draws from the
+// namespace's allocated UID range; both ranges are populated onto the namespace by annotation
+// before a pod using them can be admitted, and a pod that leaves its fsGroup or supplemental
+// groups unset is assigned a GID from that range.
+//
+// AllowedFlexVolumes narrows a flexVolume grant further than Volumes alone can: even once
+// kapi.FSTypeFlexVolume is on the allow list, admission still rejects the pod unless the
+// specific driver it requests appears here.  A nil list (privileged) allows any driver; an
+// empty, non-nil list denies all of them.
+//
+// RunAsGroup constrains PodSecurityContext.RunAsGroup/SecurityContext.RunAsGroup the same way
+// RunAsUser constrains the UID; RunAsGroupStrategyMustRunAsRange draws from the namespace's
+// allocated GID range and rejects group 0 just as RunAsUserStrategyMustRunAsNonRoot rejects UID 0.
+//
+// SeccompProfiles lists the values allowed for the seccomp.security.alpha.kubernetes.io/pod
+// annotation (and SecurityContext.SeccompProfile once it exists); "*" and "localhost/<glob>"
+// are wildcards.  DefaultAddSeccompProfile is filled in by admission when the pod requests none.
+//
+// Priority breaks ties between SCCs that a user can use: pkg/security/admission's
+// SortSCCsForPod orders the constraints a pod matches by Priority descending (nil treated as 0),
+// then by SCCRestrictivenessScore descending, then by name ascending, and ChooseSCC admits the
+// pod under the first one of those that validates.  Bootstrap priorities here are spread out,
+// and in the same relative order as SCCRestrictivenessScore, so nonroot-strict and restricted,
+// the most restrictive bootstrap SCCs, always outrank privileged, the least.
 func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string][]string, sccNameToAdditionalUsers map[string][]string) []kapi.SecurityContextConstraints {
 	// define priorities here and reference them below so it is easy to see, at a glance
-	// what we're setting
+	// what we're setting.  Priority is compared before restrictiveness: a user granted several
+	// SCCs is admitted under whichever matching SCC has the highest priority, so the most
+	// restrictive bootstrap SCC that still fits the pod should carry the highest number.
 	var (
+		securityContextConstraintsPrivilegedPriority = 0
+
+		// hostaccess grants AllowHostNetwork/Ports/PID/IPC on top of the same volume and GID
+		// laxness as hostmount-anyuid, so by SCCRestrictivenessScore it is the more permissive
+		// of the two and must sit below it.
+		securityContextConstraintsHostAccessPriority         = 4
+		securityContextConstraintsHostMountAndAnyUIDPriority = 8
+
 		// this is set to 10 to allow wiggle room for admins to set other priorities without
 		// having to adjust anyUID.
 		securityContextConstraintsAnyUIDPriority = 10
+
+		securityContextConstraintsNonRootPriority       = 15
+		securityContextConstraintsRestrictedPriority    = 20
+		securityContextConstraintsNonRootStrictPriority = 22
+
+		// denyAllowPrivilegeEscalation is shared by the SCCs below that forbid a container from
+		// gaining additional privileges via setuid binaries or no_new_privs.
+		denyAllowPrivilegeEscalation = false
+
+		// defaultSeccompProfile is applied by admission to pods that request no seccomp profile
+		// of their own.
+		defaultSeccompProfile = "runtime/default"
 	)
 
 	constraints := []kapi.SecurityContextConstraints{
@@ -70,12 +133,22 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 			RunAsUser: kapi.RunAsUserStrategyOptions{
 				Type: kapi.RunAsUserStrategyRunAsAny,
 			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				Type: kapi.RunAsGroupStrategyRunAsAny,
+			},
 			FSGroup: kapi.FSGroupStrategyOptions{
 				Type: kapi.FSGroupStrategyRunAsAny,
 			},
 			SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{
 				Type: kapi.SupplementalGroupsStrategyRunAsAny,
 			},
+			// privileged is allowed to mount any volume plugin
+			Volumes: []kapi.FSType{kapi.FSTypeAll},
+			// privileged may request any seccomp profile, including unconfined
+			SeccompProfiles: []string{"*"},
+			// the most permissive SCC; only chosen over another matching SCC if nothing
+			// more restrictive fits
+			Priority: &securityContextConstraintsPrivilegedPriority,
 		},
 		// SecurityContextConstraintNonRoot does not allow host access, allocates SELinux labels
 		// and allows the user to request a specific UID or provide the default in the dockerfile.
@@ -98,12 +171,32 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 				// the docker file contain a USER directive.
 				Type: kapi.RunAsUserStrategyMustRunAsNonRoot,
 			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				Type: kapi.RunAsGroupStrategyRunAsAny,
+			},
 			FSGroup: kapi.FSGroupStrategyOptions{
-				Type: kapi.FSGroupStrategyRunAsAny,
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.
+				Type: kapi.FSGroupStrategyMustRunAs,
 			},
 			SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{
-				Type: kapi.SupplementalGroupsStrategyRunAsAny,
+				Type: kapi.SupplementalGroupsStrategyMustRunAs,
+			},
+			Volumes: []kapi.FSType{
+				kapi.FSTypeConfigMap,
+				kapi.FSTypeDownwardAPI,
+				kapi.FSTypeEmptyDir,
+				kapi.FSTypePersistentVolumeClaim,
+				kapi.FSTypeSecret,
 			},
+			// forbid gaining more privileges than the process already has
+			AllowPrivilegeEscalation: &denyAllowPrivilegeEscalation,
+			// no flex volume drivers are whitelisted
+			AllowedFlexVolumes:       []kapi.AllowedFlexVolume{},
+			SeccompProfiles:          []string{"runtime/default", "docker/default"},
+			DefaultAddSeccompProfile: defaultSeccompProfile,
+			Priority:                 &securityContextConstraintsNonRootPriority,
 		},
 		// SecurityContextConstraintHostMountAndAnyUID is the same as the restricted scc but allows host mounts and running as any UID.
 		// Used by the PV recycler.
@@ -128,12 +221,30 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 				// will fail.
 				Type: kapi.RunAsUserStrategyRunAsAny,
 			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				Type: kapi.RunAsGroupStrategyRunAsAny,
+			},
 			FSGroup: kapi.FSGroupStrategyOptions{
-				Type: kapi.FSGroupStrategyRunAsAny,
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.
+				Type: kapi.FSGroupStrategyMustRunAs,
 			},
 			SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{
-				Type: kapi.SupplementalGroupsStrategyRunAsAny,
+				Type: kapi.SupplementalGroupsStrategyMustRunAs,
+			},
+			Volumes: []kapi.FSType{
+				kapi.FSTypeConfigMap,
+				kapi.FSTypeDownwardAPI,
+				kapi.FSTypeEmptyDir,
+				kapi.FSTypeHostPath,
+				kapi.FSTypePersistentVolumeClaim,
+				kapi.FSTypeSecret,
 			},
+			// no flex volume drivers are whitelisted
+			AllowedFlexVolumes: []kapi.AllowedFlexVolume{},
+			SeccompProfiles:    []string{"runtime/default", "docker/default", "localhost/*"},
+			Priority:           &securityContextConstraintsHostMountAndAnyUIDPriority,
 		},
 		// SecurityContextConstraintHostNS allows access to everything except privileged on the host
 		// but still allocates UIDs and SELinux.
@@ -162,12 +273,30 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 				// will fail.
 				Type: kapi.RunAsUserStrategyMustRunAsRange,
 			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				Type: kapi.RunAsGroupStrategyRunAsAny,
+			},
 			FSGroup: kapi.FSGroupStrategyOptions{
-				Type: kapi.FSGroupStrategyRunAsAny,
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.
+				Type: kapi.FSGroupStrategyMustRunAs,
 			},
 			SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{
-				Type: kapi.SupplementalGroupsStrategyRunAsAny,
+				Type: kapi.SupplementalGroupsStrategyMustRunAs,
+			},
+			Volumes: []kapi.FSType{
+				kapi.FSTypeConfigMap,
+				kapi.FSTypeDownwardAPI,
+				kapi.FSTypeEmptyDir,
+				kapi.FSTypeHostPath,
+				kapi.FSTypePersistentVolumeClaim,
+				kapi.FSTypeSecret,
 			},
+			// no flex volume drivers are whitelisted
+			AllowedFlexVolumes: []kapi.AllowedFlexVolume{},
+			SeccompProfiles:    []string{"runtime/default", "docker/default", "localhost/*"},
+			Priority:           &securityContextConstraintsHostAccessPriority,
 		},
 		// SecurityContextConstraintRestricted allows no host access and allocates UIDs and SELinux.
 		{
@@ -190,14 +319,34 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 				// will fail.
 				Type: kapi.RunAsUserStrategyMustRunAsRange,
 			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				Type: kapi.RunAsGroupStrategyRunAsAny,
+			},
 			FSGroup: kapi.FSGroupStrategyOptions{
-				Type: kapi.FSGroupStrategyRunAsAny,
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.
+				Type: kapi.FSGroupStrategyMustRunAs,
 			},
 			SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{
-				Type: kapi.SupplementalGroupsStrategyRunAsAny,
+				Type: kapi.SupplementalGroupsStrategyMustRunAs,
 			},
 			// drops unsafe caps
 			RequiredDropCapabilities: []kapi.Capability{"KILL", "MKNOD", "SYS_CHROOT", "SETUID", "SETGID"},
+			Volumes: []kapi.FSType{
+				kapi.FSTypeConfigMap,
+				kapi.FSTypeDownwardAPI,
+				kapi.FSTypeEmptyDir,
+				kapi.FSTypePersistentVolumeClaim,
+				kapi.FSTypeSecret,
+			},
+			// forbid gaining more privileges than the process already has
+			AllowPrivilegeEscalation: &denyAllowPrivilegeEscalation,
+			// no flex volume drivers are whitelisted
+			AllowedFlexVolumes:       []kapi.AllowedFlexVolume{},
+			SeccompProfiles:          []string{"runtime/default", "docker/default"},
+			DefaultAddSeccompProfile: defaultSeccompProfile,
+			Priority:                 &securityContextConstraintsRestrictedPriority,
 		},
 		// SecurityContextConstraintsAnyUID allows no host access and allocates SELinux.
 		{
@@ -217,6 +366,9 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 			RunAsUser: kapi.RunAsUserStrategyOptions{
 				Type: kapi.RunAsUserStrategyRunAsAny,
 			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				Type: kapi.RunAsGroupStrategyRunAsAny,
+			},
 			FSGroup: kapi.FSGroupStrategyOptions{
 				Type: kapi.FSGroupStrategyRunAsAny,
 			},
@@ -227,6 +379,73 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 			Priority: &securityContextConstraintsAnyUIDPriority,
 			// drops unsafe caps
 			RequiredDropCapabilities: []kapi.Capability{"KILL", "MKNOD", "SYS_CHROOT", "SETUID", "SETGID"},
+			Volumes: []kapi.FSType{
+				kapi.FSTypeConfigMap,
+				kapi.FSTypeDownwardAPI,
+				kapi.FSTypeEmptyDir,
+				kapi.FSTypePersistentVolumeClaim,
+				kapi.FSTypeSecret,
+			},
+			// forbid gaining more privileges than the process already has
+			AllowPrivilegeEscalation: &denyAllowPrivilegeEscalation,
+			// no flex volume drivers are whitelisted
+			AllowedFlexVolumes:       []kapi.AllowedFlexVolume{},
+			SeccompProfiles:          []string{"runtime/default", "docker/default"},
+			DefaultAddSeccompProfile: defaultSeccompProfile,
+		},
+		// SecurityContextConstraintNonRootStrict provides all features of the restricted SCC but
+		// additionally requires a non-root primary GID allocated to the namespace, rejecting GID 0
+		// the same way RunAsUserStrategyMustRunAsNonRoot rejects UID 0.
+		{
+			ObjectMeta: kapi.ObjectMeta{
+				Name: SecurityContextConstraintNonRootStrict,
+				Annotations: map[string]string{
+					DescriptionAnnotation: SecurityContextConstraintNonRootStrictDesc,
+				},
+			},
+			AllowEmptyDirVolumePlugin: true,
+			SELinuxContext: kapi.SELinuxContextStrategyOptions{
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.
+				Type: kapi.SELinuxStrategyMustRunAs,
+			},
+			RunAsUser: kapi.RunAsUserStrategyOptions{
+				// This strategy requires that the user request to run as a specific UID or that
+				// the docker file contain a USER directive.
+				Type: kapi.RunAsUserStrategyMustRunAsNonRoot,
+			},
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.  GID 0 is rejected the same as UID 0 is under RunAsUserStrategyMustRunAsNonRoot.
+				Type: kapi.RunAsGroupStrategyMustRunAsRange,
+			},
+			FSGroup: kapi.FSGroupStrategyOptions{
+				// This strategy requires that annotations on the namespace which will be populated
+				// by the admission controller.  If namespaces are not annotated creating the strategy
+				// will fail.
+				Type: kapi.FSGroupStrategyMustRunAs,
+			},
+			SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{
+				Type: kapi.SupplementalGroupsStrategyMustRunAs,
+			},
+			// drops unsafe caps
+			RequiredDropCapabilities: []kapi.Capability{"KILL", "MKNOD", "SYS_CHROOT", "SETUID", "SETGID"},
+			Volumes: []kapi.FSType{
+				kapi.FSTypeConfigMap,
+				kapi.FSTypeDownwardAPI,
+				kapi.FSTypeEmptyDir,
+				kapi.FSTypePersistentVolumeClaim,
+				kapi.FSTypeSecret,
+			},
+			// forbid gaining more privileges than the process already has
+			AllowPrivilegeEscalation: &denyAllowPrivilegeEscalation,
+			// no flex volume drivers are whitelisted
+			AllowedFlexVolumes:       []kapi.AllowedFlexVolume{},
+			SeccompProfiles:          []string{"runtime/default", "docker/default"},
+			DefaultAddSeccompProfile: defaultSeccompProfile,
+			Priority:                 &securityContextConstraintsNonRootStrictPriority,
 		},
 	}
 
@@ -244,6 +463,8 @@ func GetBootstrapSecurityContextConstraints(sccNameToAdditionalGroups map[string
 
 // GetBoostrapSCCAccess provides the default set of access that should be passed to GetBootstrapSecurityContextConstraints.
 func GetBoostrapSCCAccess(infraNamespace string) (map[string][]string, map[string][]string) {
+	// SecurityContextConstraintNonRootStrict is intentionally absent here: it is not granted to
+	// any user or group by default and must be requested explicitly.
 	groups := map[string][]string{
 		SecurityContextConstraintPrivileged: {ClusterAdminGroup, NodesGroup},
 		SecurityContextConstraintsAnyUID:    {ClusterAdminGroup},
@@ -258,3 +479,67 @@ func GetBoostrapSCCAccess(infraNamespace string) (map[string][]string, map[strin
 	}
 	return groups, users
 }
+
+// SCCRestrictivenessScore computes a deterministic ranking of how restrictive an SCC is,
+// for use as the tiebreaker when two SCCs a pod matches share a Priority (see
+// GetBootstrapSecurityContextConstraints).  It starts from a fully-restrictive baseline and
+// subtracts points for every privilege the SCC grants, so a lower score is more permissive and
+// a higher score is more restrictive.  It is exported so the SCC admission plugin's ordering
+// can be unit-tested on its own.
+func SCCRestrictivenessScore(scc *kapi.SecurityContextConstraints) int {
+	score := 100
+
+	if scc.AllowPrivilegedContainer {
+		score -= 40
+	}
+	if scc.AllowHostNetwork {
+		score -= 10
+	}
+	if scc.AllowHostPorts {
+		score -= 5
+	}
+	if scc.AllowHostPID {
+		score -= 10
+	}
+	if scc.AllowHostIPC {
+		score -= 10
+	}
+	if scc.AllowHostDirVolumePlugin {
+		score -= 10
+	}
+	if scc.AllowPrivilegeEscalation == nil || *scc.AllowPrivilegeEscalation {
+		score -= 5
+	}
+
+	score -= len(scc.AllowedCapabilities) * 2
+	score -= sccVolumeBreadth(scc.Volumes)
+
+	if scc.SELinuxContext.Type == kapi.SELinuxStrategyRunAsAny {
+		score -= 5
+	}
+	if scc.RunAsUser.Type == kapi.RunAsUserStrategyRunAsAny {
+		score -= 10
+	}
+	if scc.RunAsGroup.Type == kapi.RunAsGroupStrategyRunAsAny {
+		score -= 5
+	}
+	if scc.FSGroup.Type == kapi.FSGroupStrategyRunAsAny {
+		score -= 5
+	}
+	if scc.SupplementalGroups.Type == kapi.SupplementalGroupsStrategyRunAsAny {
+		score -= 5
+	}
+
+	return score
+}
+
+// sccVolumeBreadth scores how many volume plugins an SCC's Volumes allow; kapi.FSTypeAll is
+// worth as much as every other plugin combined since it allows all of them, present or future.
+func sccVolumeBreadth(volumes []kapi.FSType) int {
+	for _, volume := range volumes {
+		if volume == kapi.FSTypeAll {
+			return 20
+		}
+	}
+	return len(volumes)
+}
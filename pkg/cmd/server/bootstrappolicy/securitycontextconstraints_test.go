@@ -0,0 +1,97 @@
+package bootstrappolicy
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestSCCRestrictivenessScore(t *testing.T) {
+	denyEscalation := false
+
+	tests := map[string]struct {
+		scc   kapi.SecurityContextConstraints
+		score int
+	}{
+		"fully locked down": {
+			scc: kapi.SecurityContextConstraints{
+				AllowPrivilegeEscalation: &denyEscalation,
+				SELinuxContext:           kapi.SELinuxContextStrategyOptions{Type: kapi.SELinuxStrategyMustRunAs},
+				RunAsUser:                kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyMustRunAsRange},
+				RunAsGroup:               kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMustRunAsRange},
+				FSGroup:                  kapi.FSGroupStrategyOptions{Type: kapi.FSGroupStrategyMustRunAs},
+				SupplementalGroups:       kapi.SupplementalGroupsStrategyOptions{Type: kapi.SupplementalGroupsStrategyMustRunAs},
+			},
+			score: 100,
+		},
+		"privileged": {
+			scc: kapi.SecurityContextConstraints{
+				AllowPrivilegedContainer: true,
+				AllowHostNetwork:         true,
+				AllowHostPorts:           true,
+				AllowHostPID:             true,
+				AllowHostIPC:             true,
+				AllowHostDirVolumePlugin: true,
+				SELinuxContext:           kapi.SELinuxContextStrategyOptions{Type: kapi.SELinuxStrategyRunAsAny},
+				RunAsUser:                kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyRunAsAny},
+				RunAsGroup:               kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyRunAsAny},
+				FSGroup:                  kapi.FSGroupStrategyOptions{Type: kapi.FSGroupStrategyRunAsAny},
+				SupplementalGroups:       kapi.SupplementalGroupsStrategyOptions{Type: kapi.SupplementalGroupsStrategyRunAsAny},
+				Volumes:                  []kapi.FSType{kapi.FSTypeAll},
+			},
+			score: -40,
+		},
+		"allowed capabilities are penalized per capability": {
+			scc: kapi.SecurityContextConstraints{
+				AllowPrivilegeEscalation: &denyEscalation,
+				SELinuxContext:           kapi.SELinuxContextStrategyOptions{Type: kapi.SELinuxStrategyMustRunAs},
+				RunAsUser:                kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyMustRunAsRange},
+				RunAsGroup:               kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMustRunAsRange},
+				FSGroup:                  kapi.FSGroupStrategyOptions{Type: kapi.FSGroupStrategyMustRunAs},
+				SupplementalGroups:       kapi.SupplementalGroupsStrategyOptions{Type: kapi.SupplementalGroupsStrategyMustRunAs},
+				AllowedCapabilities:      []kapi.Capability{"NET_ADMIN", "SYS_TIME"},
+			},
+			score: 96,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := SCCRestrictivenessScore(&tc.scc); got != tc.score {
+				t.Errorf("got score %d, want %d", got, tc.score)
+			}
+		})
+	}
+}
+
+// TestBootstrapPriorityOrdering guards the invariant documented on
+// GetBootstrapSecurityContextConstraints: Priority must not contradict SCCRestrictivenessScore,
+// i.e. a bootstrap SCC with a lower Priority than another must not be strictly more restrictive
+// by score.
+func TestBootstrapPriorityOrdering(t *testing.T) {
+	constraints := GetBootstrapSecurityContextConstraints(nil, nil)
+
+	for _, a := range constraints {
+		for _, b := range constraints {
+			if a.Name == b.Name {
+				continue
+			}
+			aPriority, bPriority := priorityValue(a.Priority), priorityValue(b.Priority)
+			if aPriority >= bPriority {
+				continue
+			}
+			aScore, bScore := SCCRestrictivenessScore(&a), SCCRestrictivenessScore(&b)
+			if aScore > bScore {
+				t.Errorf("%s has lower priority (%d) than %s (%d) but is more restrictive by score (%d > %d)",
+					a.Name, aPriority, b.Name, bPriority, aScore, bScore)
+			}
+		}
+	}
+}
+
+func priorityValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,145 @@
+// Package admission implements the SecurityContextConstraint admission plugin: given the
+// bootstrap (or administrator-defined) constraints from bootstrappolicy, it validates and
+// defaults pods against whichever constraint the requesting user or service account can use.
+package admission
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// AssignSecurityContext validates pod against scc, returning an error naming the first field
+// that does not satisfy the constraint. It is the single entry point the SCC admission plugin
+// calls for each candidate constraint, in priority/restrictiveness order, stopping at the first
+// one pod satisfies. namespace supplies the GID (and, eventually, UID) ranges allocated to the
+// pod's namespace for strategies that draw from them.
+func AssignSecurityContext(scc *kapi.SecurityContextConstraints, namespace *kapi.Namespace, pod *kapi.Pod) error {
+	if err := ValidateVolumes(scc, pod); err != nil {
+		return err
+	}
+	if err := ValidateSecurityContext(scc, pod); err != nil {
+		return err
+	}
+	if err := ValidateGroups(scc, namespace, pod); err != nil {
+		return err
+	}
+	if err := ValidateRunAsGroup(scc, namespace, pod); err != nil {
+		return err
+	}
+	if err := ValidateSeccomp(scc, pod); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateSecurityContext enforces scc's AllowPrivilegeEscalation and ReadOnlyRootFilesystem
+// knobs against every container in pod, mutating containers that leave the field unset to the
+// value the SCC requires and rejecting ones that explicitly ask for something the SCC forbids.
+func ValidateSecurityContext(scc *kapi.SecurityContextConstraints, pod *kapi.Pod) error {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.SecurityContext == nil {
+			c.SecurityContext = &kapi.SecurityContext{}
+		}
+
+		if scc.AllowPrivilegeEscalation != nil && !*scc.AllowPrivilegeEscalation {
+			if c.SecurityContext.AllowPrivilegeEscalation == nil {
+				c.SecurityContext.AllowPrivilegeEscalation = scc.AllowPrivilegeEscalation
+			} else if *c.SecurityContext.AllowPrivilegeEscalation {
+				return fmt.Errorf("%s requires AllowPrivilegeEscalation to be false, but container %q requests true", scc.Name, c.Name)
+			}
+		}
+
+		if scc.ReadOnlyRootFilesystem {
+			if c.SecurityContext.ReadOnlyRootFilesystem == nil {
+				readOnly := true
+				c.SecurityContext.ReadOnlyRootFilesystem = &readOnly
+			} else if !*c.SecurityContext.ReadOnlyRootFilesystem {
+				return fmt.Errorf("%s requires ReadOnlyRootFilesystem, but container %q requests a writable root filesystem", scc.Name, c.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateVolumes rejects any pod volume whose FSType is not allowed by scc.Volumes. The
+// legacy AllowHostDirVolumePlugin and AllowEmptyDirVolumePlugin booleans are honored as an
+// additional allowance for SCCs that predate the Volumes allow list.
+func ValidateVolumes(scc *kapi.SecurityContextConstraints, pod *kapi.Pod) error {
+	for _, v := range pod.Spec.Volumes {
+		fsType, ok := volumeFSType(v)
+		if !ok {
+			continue
+		}
+		if !sccAllowsVolume(scc, fsType) {
+			return fmt.Errorf("%s does not allow volume type %q requested by volume %q", scc.Name, fsType, v.Name)
+		}
+		if fsType == kapi.FSTypeFlexVolume {
+			if err := validateFlexVolumeDriver(scc, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateFlexVolumeDriver rejects a flexVolume whose driver is not in scc.AllowedFlexVolumes.
+// A nil AllowedFlexVolumes (privileged) allows any driver; an empty, non-nil list denies all of
+// them even though kapi.FSTypeFlexVolume itself may be on the Volumes allow list.
+func validateFlexVolumeDriver(scc *kapi.SecurityContextConstraints, v kapi.Volume) error {
+	if scc.AllowedFlexVolumes == nil {
+		return nil
+	}
+	for _, allowed := range scc.AllowedFlexVolumes {
+		if allowed.Driver == v.FlexVolume.Driver {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not allow flexVolume driver %q requested by volume %q", scc.Name, v.FlexVolume.Driver, v.Name)
+}
+
+// sccAllowsVolume reports whether fsType is permitted by scc, either through its Volumes allow
+// list (kapi.FSTypeAll matches anything) or one of the legacy per-type booleans.
+func sccAllowsVolume(scc *kapi.SecurityContextConstraints, fsType kapi.FSType) bool {
+	switch fsType {
+	case kapi.FSTypeHostPath:
+		if scc.AllowHostDirVolumePlugin {
+			return true
+		}
+	case kapi.FSTypeEmptyDir:
+		if scc.AllowEmptyDirVolumePlugin {
+			return true
+		}
+	}
+	for _, allowed := range scc.Volumes {
+		if allowed == kapi.FSTypeAll || allowed == fsType {
+			return true
+		}
+	}
+	return false
+}
+
+// volumeFSType maps a pod volume source to the kapi.FSType used by an SCC's Volumes allow
+// list. The bool return is false for volume sources this admission plugin does not yet
+// recognize, in which case ValidateVolumes lets the volume through rather than guessing.
+func volumeFSType(v kapi.Volume) (kapi.FSType, bool) {
+	switch {
+	case v.HostPath != nil:
+		return kapi.FSTypeHostPath, true
+	case v.EmptyDir != nil:
+		return kapi.FSTypeEmptyDir, true
+	case v.ConfigMap != nil:
+		return kapi.FSTypeConfigMap, true
+	case v.DownwardAPI != nil:
+		return kapi.FSTypeDownwardAPI, true
+	case v.Secret != nil:
+		return kapi.FSTypeSecret, true
+	case v.PersistentVolumeClaim != nil:
+		return kapi.FSTypePersistentVolumeClaim, true
+	case v.FlexVolume != nil:
+		return kapi.FSTypeFlexVolume, true
+	default:
+		return "", false
+	}
+}
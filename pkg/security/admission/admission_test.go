@@ -0,0 +1,136 @@
+package admission
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestValidateVolumes(t *testing.T) {
+	restricted := kapi.SecurityContextConstraints{
+		Volumes: []kapi.FSType{kapi.FSTypeEmptyDir, kapi.FSTypeSecret},
+	}
+	privileged := kapi.SecurityContextConstraints{
+		Volumes: []kapi.FSType{kapi.FSTypeAll},
+	}
+
+	tests := map[string]struct {
+		scc     kapi.SecurityContextConstraints
+		volume  kapi.Volume
+		wantErr bool
+	}{
+		"allowed volume type passes": {
+			scc:    restricted,
+			volume: kapi.Volume{Name: "data", VolumeSource: kapi.VolumeSource{EmptyDir: &kapi.EmptyDirVolumeSource{}}},
+		},
+		"disallowed volume type is rejected": {
+			scc:     restricted,
+			volume:  kapi.Volume{Name: "data", VolumeSource: kapi.VolumeSource{HostPath: &kapi.HostPathVolumeSource{}}},
+			wantErr: true,
+		},
+		"FSTypeAll allows anything": {
+			scc:    privileged,
+			volume: kapi.Volume{Name: "data", VolumeSource: kapi.VolumeSource{HostPath: &kapi.HostPathVolumeSource{}}},
+		},
+		"unrecognized volume source is let through": {
+			scc:    restricted,
+			volume: kapi.Volume{Name: "data", VolumeSource: kapi.VolumeSource{}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pod := &kapi.Pod{Spec: kapi.PodSpec{Volumes: []kapi.Volume{tc.volume}}}
+			err := ValidateVolumes(&tc.scc, pod)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateFlexVolumeDriver(t *testing.T) {
+	flexVolume := kapi.Volume{
+		Name:         "flex",
+		VolumeSource: kapi.VolumeSource{FlexVolume: &kapi.FlexVolumeSource{Driver: "example/driver"}},
+	}
+
+	tests := map[string]struct {
+		allowedFlexVolumes []kapi.AllowedFlexVolume
+		wantErr            bool
+	}{
+		"nil allow list permits any driver": {
+			allowedFlexVolumes: nil,
+		},
+		"empty, non-nil allow list denies every driver": {
+			allowedFlexVolumes: []kapi.AllowedFlexVolume{},
+			wantErr:            true,
+		},
+		"driver present in the allow list is permitted": {
+			allowedFlexVolumes: []kapi.AllowedFlexVolume{{Driver: "example/driver"}},
+		},
+		"driver absent from the allow list is rejected": {
+			allowedFlexVolumes: []kapi.AllowedFlexVolume{{Driver: "other/driver"}},
+			wantErr:            true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			scc := kapi.SecurityContextConstraints{
+				Volumes:            []kapi.FSType{kapi.FSTypeFlexVolume},
+				AllowedFlexVolumes: tc.allowedFlexVolumes,
+			}
+			pod := &kapi.Pod{Spec: kapi.PodSpec{Volumes: []kapi.Volume{flexVolume}}}
+			err := ValidateVolumes(&scc, pod)
+			if tc.wantErr && err == nil {
+				t.Fatalf("expected an error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateSecurityContext(t *testing.T) {
+	denyEscalation := false
+
+	t.Run("AllowPrivilegeEscalation defaults an unset container to the SCC's value", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{AllowPrivilegeEscalation: &denyEscalation}
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "c"}}}}
+		if err := ValidateSecurityContext(&scc, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation
+		if got == nil || *got {
+			t.Errorf("AllowPrivilegeEscalation = %v, want false", got)
+		}
+	})
+
+	t.Run("AllowPrivilegeEscalation rejects a container that explicitly asks for true", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{AllowPrivilegeEscalation: &denyEscalation}
+		escalate := true
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{AllowPrivilegeEscalation: &escalate},
+		}}}}
+		if err := ValidateSecurityContext(&scc, pod); err == nil {
+			t.Fatalf("expected an error")
+		}
+	})
+
+	t.Run("ReadOnlyRootFilesystem false leaves the container's request untouched", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{}
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "c"}}}}
+		if err := ValidateSecurityContext(&scc, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pod.Spec.Containers[0].SecurityContext.ReadOnlyRootFilesystem != nil {
+			t.Errorf("expected ReadOnlyRootFilesystem to stay unset")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package admission
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// SupplementalGroupsAnnotation is populated onto a namespace with the block of GIDs allocated
+// to it, in "start/length[,start/length...]" form. FSGroupStrategyMustRunAs and
+// SupplementalGroupsStrategyMustRunAs both draw from this same block: a pod's fsGroup and
+// its supplemental GIDs are allocated out of the same per-namespace range.
+const SupplementalGroupsAnnotation = "openshift.io/sa.scc.supplemental-groups"
+
+// gidRange is one start/length block parsed out of SupplementalGroupsAnnotation.
+type gidRange struct {
+	start  int64
+	length int64
+}
+
+func (r gidRange) contains(gid int64) bool {
+	return gid >= r.start && gid < r.start+r.length
+}
+
+// parseGIDRanges parses the comma-separated "start/length" blocks in SupplementalGroupsAnnotation.
+func parseGIDRanges(annotation string) ([]gidRange, error) {
+	var ranges []gidRange
+	for _, block := range strings.Split(annotation, ",") {
+		parts := strings.SplitN(block, "/", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid gid range block %q", block)
+		}
+		start, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gid range start %q: %v", parts[0], err)
+		}
+		length, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gid range length %q: %v", parts[1], err)
+		}
+		ranges = append(ranges, gidRange{start: start, length: length})
+	}
+	return ranges, nil
+}
+
+// namespaceGIDRanges reads and parses SupplementalGroupsAnnotation off namespace.
+func namespaceGIDRanges(namespace *kapi.Namespace) ([]gidRange, error) {
+	annotation := namespace.Annotations[SupplementalGroupsAnnotation]
+	if len(annotation) == 0 {
+		return nil, fmt.Errorf("namespace %q has no %s annotation", namespace.Name, SupplementalGroupsAnnotation)
+	}
+	return parseGIDRanges(annotation)
+}
+
+// ValidateGroups enforces scc's FSGroup and SupplementalGroups strategies against pod, drawing
+// from namespace's allocated GID range for MustRunAs and mutating pod to fill in whichever
+// fields it left unset. It mirrors how UID allocation works for RunAsUserStrategyMustRunAsRange,
+// except both FSGroup and SupplementalGroups share the same namespace-allocated block.
+func ValidateGroups(scc *kapi.SecurityContextConstraints, namespace *kapi.Namespace, pod *kapi.Pod) error {
+	if scc.FSGroup.Type == kapi.FSGroupStrategyMustRunAs {
+		ranges, err := namespaceGIDRanges(namespace)
+		if err != nil {
+			return err
+		}
+		if pod.Spec.SecurityContext == nil {
+			pod.Spec.SecurityContext = &kapi.PodSecurityContext{}
+		}
+		if pod.Spec.SecurityContext.FSGroup == nil {
+			gid := ranges[0].start
+			pod.Spec.SecurityContext.FSGroup = &gid
+		} else if !gidInRanges(*pod.Spec.SecurityContext.FSGroup, ranges) {
+			return fmt.Errorf("%s requires fsGroup to fall within the namespace's allocated GID range", scc.Name)
+		}
+	}
+
+	if scc.SupplementalGroups.Type == kapi.SupplementalGroupsStrategyMustRunAs {
+		ranges, err := namespaceGIDRanges(namespace)
+		if err != nil {
+			return err
+		}
+		if pod.Spec.SecurityContext == nil {
+			pod.Spec.SecurityContext = &kapi.PodSecurityContext{}
+		}
+		if len(pod.Spec.SecurityContext.SupplementalGroups) == 0 {
+			pod.Spec.SecurityContext.SupplementalGroups = []int64{ranges[0].start}
+		} else {
+			for _, gid := range pod.Spec.SecurityContext.SupplementalGroups {
+				if !gidInRanges(gid, ranges) {
+					return fmt.Errorf("%s requires supplemental groups to fall within the namespace's allocated GID range", scc.Name)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func gidInRanges(gid int64, ranges []gidRange) bool {
+	for _, r := range ranges {
+		if r.contains(gid) {
+			return true
+		}
+	}
+	return false
+}
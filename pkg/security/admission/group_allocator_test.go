@@ -0,0 +1,129 @@
+package admission
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestParseGIDRanges(t *testing.T) {
+	tests := map[string]struct {
+		annotation string
+		want       []gidRange
+		wantErr    bool
+	}{
+		"single range": {
+			annotation: "1000000000/10000",
+			want:       []gidRange{{start: 1000000000, length: 10000}},
+		},
+		"multiple ranges": {
+			annotation: "1000000000/10000,2000000000/5000",
+			want: []gidRange{
+				{start: 1000000000, length: 10000},
+				{start: 2000000000, length: 5000},
+			},
+		},
+		"missing length": {
+			annotation: "1000000000",
+			wantErr:    true,
+		},
+		"non-numeric start": {
+			annotation: "abc/10000",
+			wantErr:    true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseGIDRanges(tc.annotation)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %d ranges, want %d", len(got), len(tc.want))
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("range %d: got %+v, want %+v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	namespaceWithRange := &kapi.Namespace{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:        "myproject",
+			Annotations: map[string]string{SupplementalGroupsAnnotation: "1000000000/10000"},
+		},
+	}
+	namespaceWithoutRange := &kapi.Namespace{ObjectMeta: kapi.ObjectMeta{Name: "unallocated"}}
+
+	mustRunAs := kapi.SecurityContextConstraints{
+		FSGroup:            kapi.FSGroupStrategyOptions{Type: kapi.FSGroupStrategyMustRunAs},
+		SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{Type: kapi.SupplementalGroupsStrategyMustRunAs},
+	}
+	runAsAny := kapi.SecurityContextConstraints{
+		FSGroup:            kapi.FSGroupStrategyOptions{Type: kapi.FSGroupStrategyRunAsAny},
+		SupplementalGroups: kapi.SupplementalGroupsStrategyOptions{Type: kapi.SupplementalGroupsStrategyRunAsAny},
+	}
+
+	t.Run("RunAsAny is a no-op even without a namespace range", func(t *testing.T) {
+		pod := &kapi.Pod{}
+		if err := ValidateGroups(&runAsAny, namespaceWithoutRange, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pod.Spec.SecurityContext != nil {
+			t.Errorf("expected no SecurityContext to be allocated, got %+v", pod.Spec.SecurityContext)
+		}
+	})
+
+	t.Run("MustRunAs defaults FSGroup and SupplementalGroups when unset", func(t *testing.T) {
+		pod := &kapi.Pod{}
+		if err := ValidateGroups(&mustRunAs, namespaceWithRange, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pod.Spec.SecurityContext == nil || pod.Spec.SecurityContext.FSGroup == nil {
+			t.Fatalf("expected FSGroup to be allocated")
+		}
+		if got, want := *pod.Spec.SecurityContext.FSGroup, int64(1000000000); got != want {
+			t.Errorf("FSGroup = %d, want %d", got, want)
+		}
+		if got, want := pod.Spec.SecurityContext.SupplementalGroups, []int64{1000000000}; len(got) != 1 || got[0] != want[0] {
+			t.Errorf("SupplementalGroups = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("MustRunAs accepts a value already inside the namespace's range", func(t *testing.T) {
+		fsGroup := int64(1000000005)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{SecurityContext: &kapi.PodSecurityContext{
+			FSGroup:            &fsGroup,
+			SupplementalGroups: []int64{1000000001, 1000000002},
+		}}}
+		if err := ValidateGroups(&mustRunAs, namespaceWithRange, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("MustRunAs rejects a value outside the namespace's range", func(t *testing.T) {
+		fsGroup := int64(1)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{SecurityContext: &kapi.PodSecurityContext{FSGroup: &fsGroup}}}
+		if err := ValidateGroups(&mustRunAs, namespaceWithRange, pod); err == nil {
+			t.Fatalf("expected an error for an out-of-range fsGroup")
+		}
+	})
+
+	t.Run("MustRunAs fails closed when the namespace has no allocated range", func(t *testing.T) {
+		pod := &kapi.Pod{}
+		if err := ValidateGroups(&mustRunAs, namespaceWithoutRange, pod); err == nil {
+			t.Fatalf("expected an error for a namespace with no allocated range")
+		}
+	})
+}
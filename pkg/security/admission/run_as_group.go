@@ -0,0 +1,113 @@
+package admission
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// ValidateRunAsGroup enforces scc's RunAsGroup strategy against pod, mirroring how RunAsUser
+// constrains the UID.
+//
+//   - MustRunAsRange draws an allocated GID from the namespace's range (the same range FSGroup
+//     and SupplementalGroups use) and rejects group 0 the same way
+//     RunAsUserStrategyMustRunAsNonRoot rejects UID 0, regardless of whether 0 happens to fall
+//     inside the allocated range.
+//   - MustRunAs requires every container's effective group to fall within scc.RunAsGroup.Ranges,
+//     defaulting an unset container to the first range's minimum.
+//   - MayRunAs runs the same check as MustRunAs but only once a container actually requests a
+//     group; a container that requests none is left alone.
+func ValidateRunAsGroup(scc *kapi.SecurityContextConstraints, namespace *kapi.Namespace, pod *kapi.Pod) error {
+	switch scc.RunAsGroup.Type {
+	case kapi.RunAsGroupStrategyMustRunAsRange:
+		ranges, err := namespaceGIDRanges(namespace)
+		if err != nil {
+			return err
+		}
+		return validateNamespaceRunAsGroup(scc, ranges, pod)
+	case kapi.RunAsGroupStrategyMustRunAs:
+		return validateExplicitRunAsGroup(scc, pod, true)
+	case kapi.RunAsGroupStrategyMayRunAs:
+		return validateExplicitRunAsGroup(scc, pod, false)
+	default:
+		return nil
+	}
+}
+
+// validateNamespaceRunAsGroup is RunAsGroupStrategyMustRunAsRange's enforcement: it draws from
+// the namespace-allocated range and special-cases group 0 the same way UID 0 is special-cased
+// for RunAsUserStrategyMustRunAsNonRoot.
+func validateNamespaceRunAsGroup(scc *kapi.SecurityContextConstraints, ranges []gidRange, pod *kapi.Pod) error {
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		runAsGroup := podRunAsGroup(pod, c)
+		if runAsGroup == nil {
+			gid := ranges[0].start
+			if c.SecurityContext == nil {
+				c.SecurityContext = &kapi.SecurityContext{}
+			}
+			c.SecurityContext.RunAsGroup = &gid
+			continue
+		}
+		if *runAsGroup == 0 {
+			return fmt.Errorf("%s does not allow container %q to run as group 0", scc.Name, c.Name)
+		}
+		if !gidInRanges(*runAsGroup, ranges) {
+			return fmt.Errorf("%s requires %q to run as a group allocated to the namespace", scc.Name, c.Name)
+		}
+	}
+	return nil
+}
+
+// validateExplicitRunAsGroup is MustRunAs/MayRunAs's shared enforcement: both validate against
+// scc.RunAsGroup.Ranges rather than a namespace-allocated block. required distinguishes MustRunAs
+// (true: every container must resolve to an in-range group, defaulting the first range's minimum
+// when unset) from MayRunAs (false: a container that requests no group is left alone).
+func validateExplicitRunAsGroup(scc *kapi.SecurityContextConstraints, pod *kapi.Pod, required bool) error {
+	ranges := idRangesToGIDRanges(scc.RunAsGroup.Ranges)
+	if len(ranges) == 0 {
+		return fmt.Errorf("%s specifies no RunAsGroup ranges to validate against", scc.Name)
+	}
+
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		runAsGroup := podRunAsGroup(pod, c)
+		if runAsGroup == nil {
+			if !required {
+				continue
+			}
+			gid := ranges[0].start
+			if c.SecurityContext == nil {
+				c.SecurityContext = &kapi.SecurityContext{}
+			}
+			c.SecurityContext.RunAsGroup = &gid
+			continue
+		}
+		if !gidInRanges(*runAsGroup, ranges) {
+			return fmt.Errorf("%s requires %q to run as a group within its allowed ranges", scc.Name, c.Name)
+		}
+	}
+	return nil
+}
+
+// idRangesToGIDRanges adapts scc.RunAsGroup.Ranges to the gidRange type group_allocator.go
+// already uses for namespace-allocated blocks, so both sources of ranges share one representation.
+func idRangesToGIDRanges(ranges []kapi.IDRange) []gidRange {
+	converted := make([]gidRange, 0, len(ranges))
+	for _, r := range ranges {
+		converted = append(converted, gidRange{start: r.Min, length: r.Max - r.Min + 1})
+	}
+	return converted
+}
+
+// podRunAsGroup resolves the effective RunAsGroup for a container: the container's own
+// SecurityContext takes precedence, falling back to the pod-level PodSecurityContext.
+func podRunAsGroup(pod *kapi.Pod, c *kapi.Container) *int64 {
+	if c.SecurityContext != nil && c.SecurityContext.RunAsGroup != nil {
+		return c.SecurityContext.RunAsGroup
+	}
+	if pod.Spec.SecurityContext != nil {
+		return pod.Spec.SecurityContext.RunAsGroup
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package admission
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestValidateRunAsGroupMustRunAsRange(t *testing.T) {
+	scc := kapi.SecurityContextConstraints{
+		RunAsGroup: kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMustRunAsRange},
+	}
+	namespace := &kapi.Namespace{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:        "myproject",
+			Annotations: map[string]string{SupplementalGroupsAnnotation: "1000000000/10000"},
+		},
+	}
+
+	t.Run("defaults an unset container to the namespace's range", func(t *testing.T) {
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "c"}}}}
+		if err := ValidateRunAsGroup(&scc, namespace, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := pod.Spec.Containers[0].SecurityContext.RunAsGroup
+		if got == nil || *got != 1000000000 {
+			t.Errorf("RunAsGroup = %v, want 1000000000", got)
+		}
+	})
+
+	t.Run("rejects group 0 even though it is never inside the allocated range", func(t *testing.T) {
+		zero := int64(0)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{RunAsGroup: &zero},
+		}}}}
+		if err := ValidateRunAsGroup(&scc, namespace, pod); err == nil {
+			t.Fatalf("expected group 0 to be rejected")
+		}
+	})
+
+	t.Run("container-level RunAsGroup takes precedence over pod-level", func(t *testing.T) {
+		podLevel := int64(1)
+		containerLevel := int64(1000000003)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{
+			SecurityContext: &kapi.PodSecurityContext{RunAsGroup: &podLevel},
+			Containers: []kapi.Container{{
+				Name:            "c",
+				SecurityContext: &kapi.SecurityContext{RunAsGroup: &containerLevel},
+			}},
+		}}
+		if err := ValidateRunAsGroup(&scc, namespace, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("rejects a group outside the namespace's range", func(t *testing.T) {
+		outOfRange := int64(5)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{RunAsGroup: &outOfRange},
+		}}}}
+		if err := ValidateRunAsGroup(&scc, namespace, pod); err == nil {
+			t.Fatalf("expected an out-of-range group to be rejected")
+		}
+	})
+}
+
+func TestValidateRunAsGroupExplicitRanges(t *testing.T) {
+	ranges := []kapi.IDRange{{Min: 100, Max: 199}}
+
+	t.Run("MustRunAs defaults an unset container to the first range's minimum", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMustRunAs, Ranges: ranges},
+		}
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "c"}}}}
+		if err := ValidateRunAsGroup(&scc, nil, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := pod.Spec.Containers[0].SecurityContext.RunAsGroup
+		if got == nil || *got != 100 {
+			t.Errorf("RunAsGroup = %v, want 100", got)
+		}
+	})
+
+	t.Run("MustRunAs rejects a group outside its ranges", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMustRunAs, Ranges: ranges},
+		}
+		outOfRange := int64(5)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{RunAsGroup: &outOfRange},
+		}}}}
+		if err := ValidateRunAsGroup(&scc, nil, pod); err == nil {
+			t.Fatalf("expected an out-of-range group to be rejected")
+		}
+	})
+
+	t.Run("MayRunAs leaves an unset container alone", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMayRunAs, Ranges: ranges},
+		}
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "c"}}}}
+		if err := ValidateRunAsGroup(&scc, nil, pod); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pod.Spec.Containers[0].SecurityContext != nil {
+			t.Errorf("expected an unset container to be left untouched")
+		}
+	})
+
+	t.Run("MayRunAs still rejects an explicit out-of-range group", func(t *testing.T) {
+		scc := kapi.SecurityContextConstraints{
+			RunAsGroup: kapi.RunAsGroupStrategyOptions{Type: kapi.RunAsGroupStrategyMayRunAs, Ranges: ranges},
+		}
+		outOfRange := int64(5)
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{RunAsGroup: &outOfRange},
+		}}}}
+		if err := ValidateRunAsGroup(&scc, nil, pod); err == nil {
+			t.Fatalf("expected an out-of-range group to be rejected")
+		}
+	})
+}
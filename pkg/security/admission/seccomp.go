@@ -0,0 +1,63 @@
+package admission
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// SeccompPodAnnotation is the pod annotation that carries the requested seccomp profile.
+const SeccompPodAnnotation = "seccomp.security.alpha.kubernetes.io/pod"
+
+// ValidateSeccomp enforces scc's SeccompProfiles allow list against pod's seccomp annotation,
+// defaulting it to scc.DefaultAddSeccompProfile when the pod requests none. "*" matches any
+// profile; "localhost/<glob>" matches any localhost profile whose suffix matches the glob.
+func ValidateSeccomp(scc *kapi.SecurityContextConstraints, pod *kapi.Pod) error {
+	if len(scc.SeccompProfiles) == 0 {
+		return nil
+	}
+
+	profile := pod.Annotations[SeccompPodAnnotation]
+	if len(profile) == 0 {
+		if len(scc.DefaultAddSeccompProfile) == 0 {
+			return nil
+		}
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[SeccompPodAnnotation] = scc.DefaultAddSeccompProfile
+		return nil
+	}
+
+	for _, allowed := range scc.SeccompProfiles {
+		if seccompProfileMatches(allowed, profile) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not allow seccomp profile %q", scc.Name, profile)
+}
+
+// seccompProfileMatches reports whether requested satisfies the allow-list entry allowed,
+// which may be an exact profile name, "*" (any profile), or "localhost/<glob>" (any localhost
+// profile whose path suffix matches glob).
+func seccompProfileMatches(allowed, requested string) bool {
+	if allowed == "*" {
+		return true
+	}
+	if allowed == requested {
+		return true
+	}
+
+	const localhostPrefix = "localhost/"
+	if strings.HasPrefix(allowed, localhostPrefix) && strings.HasPrefix(requested, localhostPrefix) {
+		pattern := strings.TrimPrefix(allowed, localhostPrefix)
+		if pattern == "*" {
+			return true
+		}
+		matched, err := filepath.Match(pattern, strings.TrimPrefix(requested, localhostPrefix))
+		return err == nil && matched
+	}
+	return false
+}
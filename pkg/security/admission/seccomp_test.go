@@ -0,0 +1,82 @@
+package admission
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestValidateSeccomp(t *testing.T) {
+	tests := map[string]struct {
+		scc         kapi.SecurityContextConstraints
+		annotations map[string]string
+		wantErr     bool
+		wantProfile string
+	}{
+		"no SeccompProfiles means no restriction": {
+			scc:         kapi.SecurityContextConstraints{},
+			annotations: map[string]string{SeccompPodAnnotation: "anything"},
+			wantProfile: "anything",
+		},
+		"exact match is allowed": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"runtime/default"}},
+			annotations: map[string]string{SeccompPodAnnotation: "runtime/default"},
+			wantProfile: "runtime/default",
+		},
+		"profile not on the allow list is rejected": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"runtime/default"}},
+			annotations: map[string]string{SeccompPodAnnotation: "unconfined"},
+			wantErr:     true,
+		},
+		"wildcard * allows any profile": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"*"}},
+			annotations: map[string]string{SeccompPodAnnotation: "unconfined"},
+			wantProfile: "unconfined",
+		},
+		"localhost/* allows any localhost profile": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"localhost/*"}},
+			annotations: map[string]string{SeccompPodAnnotation: "localhost/my-profile.json"},
+			wantProfile: "localhost/my-profile.json",
+		},
+		"localhost glob matches a matching suffix": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"localhost/profiles/*.json"}},
+			annotations: map[string]string{SeccompPodAnnotation: "localhost/profiles/app.json"},
+			wantProfile: "localhost/profiles/app.json",
+		},
+		"localhost glob rejects a non-matching suffix": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"localhost/profiles/*.json"}},
+			annotations: map[string]string{SeccompPodAnnotation: "localhost/other/app.json"},
+			wantErr:     true,
+		},
+		"unset annotation defaults to DefaultAddSeccompProfile": {
+			scc: kapi.SecurityContextConstraints{
+				SeccompProfiles:          []string{"runtime/default"},
+				DefaultAddSeccompProfile: "runtime/default",
+			},
+			wantProfile: "runtime/default",
+		},
+		"unset annotation with no default is left alone": {
+			scc:         kapi.SecurityContextConstraints{SeccompProfiles: []string{"runtime/default"}},
+			wantProfile: "",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			pod := &kapi.Pod{ObjectMeta: kapi.ObjectMeta{Annotations: tc.annotations}}
+			err := ValidateSeccomp(&tc.scc, pod)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := pod.Annotations[SeccompPodAnnotation]; got != tc.wantProfile {
+				t.Errorf("profile annotation = %q, want %q", got, tc.wantProfile)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package admission
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/openshift/origin/pkg/cmd/server/bootstrappolicy"
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// SortSCCsForPod orders constraints the way the SCC admission plugin must consider them for a
+// pod: by Priority descending (nil treated as 0), then by bootstrappolicy.SCCRestrictivenessScore
+// descending, then by name ascending (see
+// bootstrappolicy.GetBootstrapSecurityContextConstraints). It returns a new slice; constraints
+// itself is left untouched.
+func SortSCCsForPod(constraints []kapi.SecurityContextConstraints) []kapi.SecurityContextConstraints {
+	sorted := make([]kapi.SecurityContextConstraints, len(constraints))
+	copy(sorted, constraints)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := &sorted[i], &sorted[j]
+		if pa, pb := priorityValue(a.Priority), priorityValue(b.Priority); pa != pb {
+			return pa > pb
+		}
+		if sa, sb := bootstrappolicy.SCCRestrictivenessScore(a), bootstrappolicy.SCCRestrictivenessScore(b); sa != sb {
+			return sa > sb
+		}
+		return a.Name < b.Name
+	})
+	return sorted
+}
+
+// ChooseSCC tries constraints against pod in SortSCCsForPod order and returns the first one
+// that admits it. Each candidate is tried against a scratch copy of pod, so a rejected SCC's
+// partial mutations (defaulted fields, allocated GIDs, ...) never leak into later attempts or
+// the caller's pod; once a candidate succeeds its mutations are copied onto pod for real.
+func ChooseSCC(constraints []kapi.SecurityContextConstraints, namespace *kapi.Namespace, pod *kapi.Pod) (*kapi.SecurityContextConstraints, error) {
+	sorted := SortSCCsForPod(constraints)
+
+	var lastErr error
+	for i := range sorted {
+		trial := copyPodForTrial(pod)
+		if err := AssignSecurityContext(&sorted[i], namespace, trial); err != nil {
+			lastErr = err
+			continue
+		}
+		*pod = *trial
+		return &sorted[i], nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no security context constraints were provided")
+	}
+	return nil, fmt.Errorf("unable to validate pod %q against any security context constraint: %v", pod.Name, lastErr)
+}
+
+func priorityValue(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// copyPodForTrial copies pod deep enough for AssignSecurityContext's validators: the container
+// slice, each container's SecurityContext, the pod-level SecurityContext, and the annotation
+// map, all of which a trial may mutate.
+func copyPodForTrial(pod *kapi.Pod) *kapi.Pod {
+	trial := *pod
+
+	trial.Spec.Containers = make([]kapi.Container, len(pod.Spec.Containers))
+	copy(trial.Spec.Containers, pod.Spec.Containers)
+	for i := range trial.Spec.Containers {
+		if sc := trial.Spec.Containers[i].SecurityContext; sc != nil {
+			copied := *sc
+			trial.Spec.Containers[i].SecurityContext = &copied
+		}
+	}
+
+	if pod.Spec.SecurityContext != nil {
+		copied := *pod.Spec.SecurityContext
+		trial.Spec.SecurityContext = &copied
+	}
+
+	if pod.Annotations != nil {
+		trial.Annotations = make(map[string]string, len(pod.Annotations))
+		for k, v := range pod.Annotations {
+			trial.Annotations[k] = v
+		}
+	}
+
+	return &trial
+}
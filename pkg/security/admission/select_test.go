@@ -0,0 +1,111 @@
+package admission
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestSortSCCsForPod(t *testing.T) {
+	low := kapi.SecurityContextConstraints{ObjectMeta: kapi.ObjectMeta{Name: "low-priority"}, Priority: intPtr(1)}
+	highB := kapi.SecurityContextConstraints{ObjectMeta: kapi.ObjectMeta{Name: "high-priority-b"}, Priority: intPtr(10)}
+	highA := kapi.SecurityContextConstraints{ObjectMeta: kapi.ObjectMeta{Name: "high-priority-a"}, Priority: intPtr(10)}
+	noPriority := kapi.SecurityContextConstraints{ObjectMeta: kapi.ObjectMeta{Name: "no-priority"}}
+
+	sorted := SortSCCsForPod([]kapi.SecurityContextConstraints{low, noPriority, highB, highA})
+
+	want := []string{"high-priority-a", "high-priority-b", "low-priority", "no-priority"}
+	for i, name := range want {
+		if sorted[i].Name != name {
+			t.Fatalf("sorted[%d].Name = %q, want %q (order: %v)", i, sorted[i].Name, name, names(sorted))
+		}
+	}
+}
+
+func names(sccs []kapi.SecurityContextConstraints) []string {
+	out := make([]string, len(sccs))
+	for i, scc := range sccs {
+		out[i] = scc.Name
+	}
+	return out
+}
+
+func TestChooseSCC(t *testing.T) {
+	trueVal := true
+	restricted := kapi.SecurityContextConstraints{
+		ObjectMeta:               kapi.ObjectMeta{Name: "restricted"},
+		Priority:                 intPtr(20),
+		AllowPrivilegeEscalation: &trueVal,
+	}
+	falseVal := false
+	restrictedDenyEscalation := kapi.SecurityContextConstraints{
+		ObjectMeta:               kapi.ObjectMeta{Name: "restricted-deny-escalation"},
+		Priority:                 intPtr(20),
+		AllowPrivilegeEscalation: &falseVal,
+	}
+	anyuid := kapi.SecurityContextConstraints{
+		ObjectMeta: kapi.ObjectMeta{Name: "anyuid"},
+		Priority:   intPtr(10),
+	}
+
+	t.Run("picks the highest-priority SCC that admits the pod", func(t *testing.T) {
+		escalation := true
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{AllowPrivilegeEscalation: &escalation},
+		}}}}
+
+		chosen, err := ChooseSCC([]kapi.SecurityContextConstraints{anyuid, restrictedDenyEscalation, restricted}, nil, pod)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if chosen.Name != "restricted" {
+			t.Errorf("chosen SCC = %q, want %q", chosen.Name, "restricted")
+		}
+	})
+
+	t.Run("falls through to a lower-priority SCC when a higher one rejects the pod", func(t *testing.T) {
+		escalation := true
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{AllowPrivilegeEscalation: &escalation},
+		}}}}
+
+		chosen, err := ChooseSCC([]kapi.SecurityContextConstraints{restrictedDenyEscalation, anyuid}, nil, pod)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if chosen.Name != "anyuid" {
+			t.Errorf("chosen SCC = %q, want %q", chosen.Name, "anyuid")
+		}
+	})
+
+	t.Run("the chosen SCC's mutations are applied to the caller's pod", func(t *testing.T) {
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "c"}}}}
+
+		chosen, err := ChooseSCC([]kapi.SecurityContextConstraints{restrictedDenyEscalation, anyuid}, nil, pod)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if chosen.Name != "restricted-deny-escalation" {
+			t.Errorf("chosen SCC = %q, want %q", chosen.Name, "restricted-deny-escalation")
+		}
+		if got := pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation; got == nil || *got {
+			t.Errorf("AllowPrivilegeEscalation = %v, want false", got)
+		}
+	})
+
+	t.Run("returns an error when no SCC admits the pod", func(t *testing.T) {
+		escalation := true
+		pod := &kapi.Pod{Spec: kapi.PodSpec{Containers: []kapi.Container{{
+			Name:            "c",
+			SecurityContext: &kapi.SecurityContext{AllowPrivilegeEscalation: &escalation},
+		}}}}
+
+		if _, err := ChooseSCC([]kapi.SecurityContextConstraints{restrictedDenyEscalation}, nil, pod); err == nil {
+			t.Fatalf("expected an error, got none")
+		}
+	})
+}